@@ -9,3 +9,7 @@ require (
 )
 
 require github.com/shurcooL/graphql v0.0.0-20230722043721-ed46e5a46466 // indirect
+
+require common v0.0.0
+
+replace common => ../common
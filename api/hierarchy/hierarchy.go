@@ -0,0 +1,178 @@
+package hierarchy
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"common/githost"
+
+	"github.com/shurcooL/githubv4"
+)
+
+const defaultMaxParentTraversal = 10
+
+// GraphQLExecutor is the subset of *githubv4.Client used to walk the parent chain.
+type GraphQLExecutor interface {
+	Query(ctx context.Context, q interface{}, variables map[string]interface{}) error
+}
+
+// IssueRef identifies an issue by its repository coordinates.
+type IssueRef struct {
+	Org    string `json:"org"`
+	Repo   string `json:"repo"`
+	Number int    `json:"number"`
+}
+
+func (r IssueRef) String() string {
+	return fmt.Sprintf("%s/%s#%d", r.Org, r.Repo, r.Number)
+}
+
+// ParentRef selects an issue's parent together with the repository it lives in.
+type ParentRef struct {
+	Number     githubv4.Int
+	Repository struct {
+		Name  githubv4.String
+		Owner struct {
+			Login githubv4.String
+		}
+	}
+}
+
+// IssueRef returns the parent as an IssueRef, or nil when the issue has no parent.
+func (p ParentRef) IssueRef() *IssueRef {
+	if p.Number == 0 {
+		return nil
+	}
+	return &IssueRef{
+		Org:    string(p.Repository.Owner.Login),
+		Repo:   string(p.Repository.Name),
+		Number: int(p.Number),
+	}
+}
+
+type parentQuery struct {
+	Repository struct {
+		Issue struct {
+			Url    githubv4.String
+			Parent ParentRef `graphql:"parent"`
+		} `graphql:"issue(number: $issueNumber)"`
+	} `graphql:"repository(owner: $owner, name: $name)"`
+}
+
+// ParentLookup loads an issue and returns its parent, or nil when it has none.
+type ParentLookup func(ref IssueRef) (*IssueRef, error)
+
+// FindTopParent follows the parent links of issueURL and returns the URL of the root issue.
+// See WalkParents for how cross-repository parents, loops and MAX_PARENT_TRAVERSAL are handled.
+func FindTopParent(client GraphQLExecutor, ctx context.Context, issueURL string) (string, error) {
+	start, err := parseIssueRef(issueURL, githost.BaseURL())
+	if err != nil {
+		return "", err
+	}
+
+	urls := make(map[IssueRef]string)
+	_, top, _, err := WalkParents(start, func(ref IssueRef) (*IssueRef, error) {
+		var q parentQuery
+		if err := client.Query(ctx, &q, IssueVariables(ref)); err != nil {
+			return nil, fmt.Errorf("query error: %w", err)
+		}
+		urls[ref] = string(q.Repository.Issue.Url)
+		return q.Repository.Issue.Parent.IssueRef(), nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return urls[top], nil
+}
+
+// WalkParents returns the issues from start up to its root, following parents into other
+// repositories. top is the root, or the issue where a loop closes if one is detected, in which
+// case loop is true. A chain with more than MAX_PARENT_TRAVERSAL parent hops is an error.
+func WalkParents(start IssueRef, lookup ParentLookup) (chain []IssueRef, top IssueRef, loop bool, err error) {
+	maxTraversal := maxParentTraversal()
+	visitedIssues := make(map[IssueRef]bool)
+	current := start
+
+	for depth := 0; ; depth++ {
+		if visitedIssues[current] {
+			return chain, current, true, nil
+		}
+		visitedIssues[current] = true
+		chain = append(chain, current)
+
+		next, err := lookup(current)
+		if err != nil {
+			return nil, IssueRef{}, false, err
+		}
+		if next == nil {
+			return chain, current, false, nil
+		}
+		if depth >= maxTraversal {
+			return nil, IssueRef{}, false, fmt.Errorf("parent chain of %s exceeds %d levels", start, maxTraversal)
+		}
+		if next.Org == "" || next.Repo == "" {
+			return nil, IssueRef{}, false, fmt.Errorf("parent #%d of %s has no repository", next.Number, current)
+		}
+		current = *next
+	}
+}
+
+// IssueVariables returns the owner, name and issueNumber variables for ref.
+func IssueVariables(ref IssueRef) map[string]interface{} {
+	return map[string]interface{}{
+		"owner":       githubv4.String(ref.Org),
+		"name":        githubv4.String(ref.Repo),
+		"issueNumber": githubv4.Int(ref.Number),
+	}
+}
+
+// ParseIssueURL splits an issue URL under baseURL into its org, repo and number.
+// baseURL may carry a path prefix, as some GitHub Enterprise installations do.
+func ParseIssueURL(rawURL, baseURL string) (org, repo string, number int, err error) {
+	base, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil {
+		return "", "", 0, fmt.Errorf("invalid base URL %q: %w", baseURL, err)
+	}
+	if base.Scheme == "" || base.Host == "" {
+		return "", "", 0, fmt.Errorf("invalid base URL %q: scheme and host are required, e.g. https://github.example.com", baseURL)
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", "", 0, fmt.Errorf("invalid issue URL %q: %w", rawURL, err)
+	}
+	if !strings.EqualFold(u.Host, base.Host) || !strings.HasPrefix(u.Path, base.Path+"/") {
+		return "", "", 0, fmt.Errorf("issue URL %q is not under %s", rawURL, baseURL)
+	}
+
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(u.Path, base.Path), "/"), "/")
+	if len(parts) != 4 || parts[2] != "issues" {
+		return "", "", 0, fmt.Errorf("invalid issue URL %q", rawURL)
+	}
+
+	number, err = strconv.Atoi(parts[3])
+	if err != nil || number <= 0 {
+		return "", "", 0, fmt.Errorf("invalid issue number in URL %q", rawURL)
+	}
+
+	return parts[0], parts[1], number, nil
+}
+
+func parseIssueRef(rawURL, baseURL string) (IssueRef, error) {
+	org, repo, number, err := ParseIssueURL(rawURL, baseURL)
+	if err != nil {
+		return IssueRef{}, err
+	}
+	return IssueRef{Org: org, Repo: repo, Number: number}, nil
+}
+
+func maxParentTraversal() int {
+	n, err := strconv.Atoi(os.Getenv("MAX_PARENT_TRAVERSAL"))
+	if err != nil || n <= 0 {
+		return defaultMaxParentTraversal
+	}
+	return n
+}
@@ -0,0 +1,280 @@
+package hierarchy_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"api/hierarchy"
+	"api/hierarchy/hierarchytest"
+)
+
+func TestFindTopParent(t *testing.T) {
+	a := hierarchy.IssueRef{Org: "org", Repo: "app", Number: 3}
+	b := hierarchy.IssueRef{Org: "org", Repo: "app", Number: 2}
+	c := hierarchy.IssueRef{Org: "other", Repo: "roadmap", Number: 1}
+
+	tests := []struct {
+		name    string
+		parents map[hierarchy.IssueRef]*hierarchy.IssueRef
+		start   hierarchy.IssueRef
+		want    hierarchy.IssueRef
+		queries int
+	}{
+		{
+			name:    "no parent",
+			parents: map[hierarchy.IssueRef]*hierarchy.IssueRef{a: nil},
+			start:   a,
+			want:    a,
+			queries: 1,
+		},
+		{
+			name:    "cross-repository chain",
+			parents: map[hierarchy.IssueRef]*hierarchy.IssueRef{a: &b, b: &c, c: nil},
+			start:   a,
+			want:    c,
+			queries: 3,
+		},
+		{
+			name:    "loop",
+			parents: map[hierarchy.IssueRef]*hierarchy.IssueRef{a: &b, b: &a},
+			start:   a,
+			want:    a,
+			queries: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &hierarchytest.FakeClient{Parents: tt.parents}
+			got, err := hierarchy.FindTopParent(client, context.Background(), hierarchytest.IssueURL(tt.start))
+			if err != nil {
+				t.Fatalf("FindTopParent() error = %v", err)
+			}
+			if got != hierarchytest.IssueURL(tt.want) {
+				t.Errorf("FindTopParent() = %q, want %q", got, hierarchytest.IssueURL(tt.want))
+			}
+			if client.Queries != tt.queries {
+				t.Errorf("queries = %d, want %d", client.Queries, tt.queries)
+			}
+		})
+	}
+}
+
+func TestFindTopParentMaxTraversal(t *testing.T) {
+	t.Setenv("MAX_PARENT_TRAVERSAL", "2")
+
+	parents := map[hierarchy.IssueRef]*hierarchy.IssueRef{}
+	for n := 1; n <= 4; n++ {
+		ref := hierarchy.IssueRef{Org: "org", Repo: "app", Number: n}
+		parent := hierarchy.IssueRef{Org: "org", Repo: "app", Number: n + 1}
+		parents[ref] = &parent
+	}
+	parents[hierarchy.IssueRef{Org: "org", Repo: "app", Number: 5}] = nil
+
+	client := &hierarchytest.FakeClient{Parents: parents}
+	_, err := hierarchy.FindTopParent(client, context.Background(), "https://github.com/org/app/issues/1")
+	if err == nil || !strings.Contains(err.Error(), "exceeds 2 levels") {
+		t.Fatalf("FindTopParent() error = %v, want exceeds 2 levels", err)
+	}
+
+	t.Setenv("MAX_PARENT_TRAVERSAL", "4")
+	got, err := hierarchy.FindTopParent(&hierarchytest.FakeClient{Parents: parents}, context.Background(), "https://github.com/org/app/issues/1")
+	if err != nil {
+		t.Fatalf("FindTopParent() error = %v", err)
+	}
+	if want := "https://github.com/org/app/issues/5"; got != want {
+		t.Errorf("FindTopParent() = %q, want %q", got, want)
+	}
+}
+
+// lookupFrom returns a ParentLookup over a fixed parent map.
+func lookupFrom(parents map[hierarchy.IssueRef]*hierarchy.IssueRef) hierarchy.ParentLookup {
+	return func(ref hierarchy.IssueRef) (*hierarchy.IssueRef, error) {
+		return parents[ref], nil
+	}
+}
+
+func TestWalkParents(t *testing.T) {
+	a := hierarchy.IssueRef{Org: "org", Repo: "app", Number: 3}
+	b := hierarchy.IssueRef{Org: "other", Repo: "roadmap", Number: 1}
+
+	tests := []struct {
+		name      string
+		parents   map[hierarchy.IssueRef]*hierarchy.IssueRef
+		wantChain []hierarchy.IssueRef
+		wantTop   hierarchy.IssueRef
+		wantLoop  bool
+	}{
+		{
+			name:      "no parent",
+			parents:   map[hierarchy.IssueRef]*hierarchy.IssueRef{},
+			wantChain: []hierarchy.IssueRef{a},
+			wantTop:   a,
+		},
+		{
+			name:      "cross-repository parent",
+			parents:   map[hierarchy.IssueRef]*hierarchy.IssueRef{a: &b},
+			wantChain: []hierarchy.IssueRef{a, b},
+			wantTop:   b,
+		},
+		{
+			name:      "loop",
+			parents:   map[hierarchy.IssueRef]*hierarchy.IssueRef{a: &b, b: &a},
+			wantChain: []hierarchy.IssueRef{a, b},
+			wantTop:   a,
+			wantLoop:  true,
+		},
+		{
+			name:      "issue is its own parent",
+			parents:   map[hierarchy.IssueRef]*hierarchy.IssueRef{a: &a},
+			wantChain: []hierarchy.IssueRef{a},
+			wantTop:   a,
+			wantLoop:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain, top, loop, err := hierarchy.WalkParents(a, lookupFrom(tt.parents))
+			if err != nil {
+				t.Fatalf("WalkParents() error = %v", err)
+			}
+			if !reflect.DeepEqual(chain, tt.wantChain) || top != tt.wantTop || loop != tt.wantLoop {
+				t.Errorf("WalkParents() = %v, %s, %v, want %v, %s, %v", chain, top, loop, tt.wantChain, tt.wantTop, tt.wantLoop)
+			}
+		})
+	}
+}
+
+func TestWalkParentsErrors(t *testing.T) {
+	a := hierarchy.IssueRef{Org: "org", Repo: "app", Number: 3}
+
+	errLookup := errors.New("lookup failed")
+	_, _, _, err := hierarchy.WalkParents(a, func(hierarchy.IssueRef) (*hierarchy.IssueRef, error) {
+		return nil, errLookup
+	})
+	if !errors.Is(err, errLookup) {
+		t.Errorf("WalkParents() error = %v, want %v", err, errLookup)
+	}
+
+	noRepo := hierarchy.IssueRef{Number: 1}
+	_, _, _, err = hierarchy.WalkParents(a, lookupFrom(map[hierarchy.IssueRef]*hierarchy.IssueRef{a: &noRepo}))
+	if err == nil || !strings.Contains(err.Error(), "has no repository") {
+		t.Errorf("WalkParents() error = %v, want has no repository", err)
+	}
+}
+
+func TestParseIssueURL(t *testing.T) {
+	tests := []struct {
+		name       string
+		rawURL     string
+		baseURL    string
+		wantOrg    string
+		wantRepo   string
+		wantNumber int
+		wantErr    string
+	}{
+		{
+			name:       "github.com",
+			rawURL:     "https://github.com/org/app/issues/12",
+			baseURL:    "https://github.com",
+			wantOrg:    "org",
+			wantRepo:   "app",
+			wantNumber: 12,
+		},
+		{
+			name:       "GHE host",
+			rawURL:     "https://github.example.com/org/app/issues/7",
+			baseURL:    "https://github.example.com",
+			wantOrg:    "org",
+			wantRepo:   "app",
+			wantNumber: 7,
+		},
+		{
+			name:       "GHE with path prefix",
+			rawURL:     "https://example.com/github/org/app/issues/7",
+			baseURL:    "https://example.com/github",
+			wantOrg:    "org",
+			wantRepo:   "app",
+			wantNumber: 7,
+		},
+		{
+			name:       "trailing slash on base URL",
+			rawURL:     "https://example.com/github/org/app/issues/7",
+			baseURL:    "https://example.com/github/",
+			wantOrg:    "org",
+			wantRepo:   "app",
+			wantNumber: 7,
+		},
+		{
+			name:    "host mismatch",
+			rawURL:  "https://github.com/org/app/issues/12",
+			baseURL: "https://github.example.com",
+			wantErr: "is not under",
+		},
+		{
+			name:    "path prefix mismatch",
+			rawURL:  "https://example.com/gitlab/org/app/issues/7",
+			baseURL: "https://example.com/github",
+			wantErr: "is not under",
+		},
+		{
+			name:    "pull request path",
+			rawURL:  "https://github.com/org/app/pull/12",
+			baseURL: "https://github.com",
+			wantErr: "invalid issue URL",
+		},
+		{
+			name:    "zero issue number",
+			rawURL:  "https://github.com/org/app/issues/0",
+			baseURL: "https://github.com",
+			wantErr: "invalid issue number",
+		},
+		{
+			name:    "non-numeric issue number",
+			rawURL:  "https://github.com/org/app/issues/abc",
+			baseURL: "https://github.com",
+			wantErr: "invalid issue number",
+		},
+		{
+			name:    "base URL without scheme",
+			rawURL:  "https://github.example.com/org/app/issues/7",
+			baseURL: "github.example.com",
+			wantErr: "scheme and host are required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			org, repo, number, err := hierarchy.ParseIssueURL(tt.rawURL, tt.baseURL)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParseIssueURL() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseIssueURL() error = %v", err)
+			}
+			if org != tt.wantOrg || repo != tt.wantRepo || number != tt.wantNumber {
+				t.Errorf("ParseIssueURL() = %s/%s#%d, want %s/%s#%d", org, repo, number, tt.wantOrg, tt.wantRepo, tt.wantNumber)
+			}
+		})
+	}
+}
+
+func TestFindTopParentUsesGitHubBaseURL(t *testing.T) {
+	t.Setenv("GITHUB_BASE_URL", "https://github.example.com")
+	a := hierarchy.IssueRef{Org: "org", Repo: "app", Number: 3}
+	client := &hierarchytest.FakeClient{Parents: map[hierarchy.IssueRef]*hierarchy.IssueRef{a: nil}}
+
+	if _, err := hierarchy.FindTopParent(client, context.Background(), "https://github.example.com/org/app/issues/3"); err != nil {
+		t.Fatalf("FindTopParent() error = %v", err)
+	}
+	if _, err := hierarchy.FindTopParent(client, context.Background(), hierarchytest.IssueURL(a)); err == nil {
+		t.Error("FindTopParent() accepted a github.com URL with a GHE base URL")
+	}
+}
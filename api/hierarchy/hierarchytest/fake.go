@@ -0,0 +1,62 @@
+// Package hierarchytest provides a fake GraphQL client for code that walks issue parents.
+package hierarchytest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"api/hierarchy"
+
+	"github.com/shurcooL/githubv4"
+)
+
+// FakeClient answers issue queries keyed by owner, name and issueNumber from Parents,
+// which maps each known issue to its parent, or to nil for a root issue. The response
+// carries number, title, url, state and parent, so any query selecting a subset of
+// those fields can be decoded.
+type FakeClient struct {
+	Parents map[hierarchy.IssueRef]*hierarchy.IssueRef
+	Queries int
+}
+
+func (f *FakeClient) Query(ctx context.Context, q interface{}, variables map[string]interface{}) error {
+	f.Queries++
+	ref := hierarchy.IssueRef{
+		Org:    string(variables["owner"].(githubv4.String)),
+		Repo:   string(variables["name"].(githubv4.String)),
+		Number: int(variables["issueNumber"].(githubv4.Int)),
+	}
+	parent, ok := f.Parents[ref]
+	if !ok {
+		return fmt.Errorf("issue %s not found", ref)
+	}
+
+	issue := map[string]interface{}{
+		"number": ref.Number,
+		"title":  fmt.Sprintf("Issue %s", ref),
+		"url":    IssueURL(ref),
+		"state":  "OPEN",
+	}
+	if parent != nil {
+		issue["parent"] = map[string]interface{}{
+			"number": parent.Number,
+			"repository": map[string]interface{}{
+				"name":  parent.Repo,
+				"owner": map[string]interface{}{"login": parent.Org},
+			},
+		}
+	}
+	data, err := json.Marshal(map[string]interface{}{
+		"repository": map[string]interface{}{"issue": issue},
+	})
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, q)
+}
+
+// IssueURL returns the github.com URL of ref.
+func IssueURL(ref hierarchy.IssueRef) string {
+	return fmt.Sprintf("https://github.com/%s/%s/issues/%d", ref.Org, ref.Repo, ref.Number)
+}
@@ -2,10 +2,18 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"os"
 	"strconv"
 
+	"common/audit"
+	"common/githost"
+	"common/useragent"
+	"common/version"
+
+	"api/hierarchy"
+
 	"github.com/joho/godotenv"
 	"github.com/shurcooL/githubv4"
 	"golang.org/x/oauth2"
@@ -24,13 +32,14 @@ type Author struct {
 }
 
 type Issue struct {
-	CreatedAt   githubv4.DateTime `json:"created_at"`
-	ClosedAt    githubv4.DateTime `json:"closed_at"`
-	State       string            `json:"state"`
-	Author      Author            `json:"author"`
-	Body        string            `json:"body"`
-	Labels      []Label           `json:"labels"`
-	LinkedItems []LinkedItem      `json:"linked_items,omitempty"`
+	CreatedAt   githubv4.DateTime    `json:"created_at"`
+	ClosedAt    githubv4.DateTime    `json:"closed_at"`
+	State       string               `json:"state"`
+	Author      Author               `json:"author"`
+	Body        string               `json:"body"`
+	Labels      []Label              `json:"labels"`
+	LinkedItems []LinkedItem         `json:"linked_items,omitempty"`
+	ParentChain []hierarchy.IssueRef `json:"parent_chain,omitempty"`
 }
 
 type Label struct {
@@ -53,59 +62,47 @@ type IssueFragment struct {
 			Name githubv4.String
 		}
 	} `graphql:"labels(first: 100)"`
-	Parent struct {
-		Id     githubv4.ID
-		Number githubv4.Int
-	} `graphql:"parent"`
 }
 
 type topParentQuery struct {
 	Repository struct {
-		Issue IssueFragment `graphql:"issue(number: $issueNumber)"`
+		Issue struct {
+			IssueFragment
+			Parent hierarchy.ParentRef `graphql:"parent"`
+		} `graphql:"issue(number: $issueNumber)"`
 	} `graphql:"repository(owner: $owner, name: $name)"`
 }
 
-func getTopParentIssue(ctx context.Context, client *githubv4.Client, org, repo string, issueNumber int) (*Issue, error) {
-	var q topParentQuery
-	variables := map[string]interface{}{
-		"owner":       githubv4.String(org),
-		"name":        githubv4.String(repo),
-		"issueNumber": githubv4.Int(issueNumber),
-	}
-
-	visitedIssues := make(map[int]bool)
-
-	for {
-		err := client.Query(ctx, &q, variables)
-		if err != nil {
+func getTopParentIssue(ctx context.Context, client hierarchy.GraphQLExecutor, org, repo string, issueNumber int) (*Issue, error) {
+	// 親Issueは別リポジトリにある可能性があるため、親のリポジトリも取得して辿る
+	issues := make(map[hierarchy.IssueRef]IssueFragment)
+	chain, top, loop, err := hierarchy.WalkParents(hierarchy.IssueRef{Org: org, Repo: repo, Number: issueNumber}, func(ref hierarchy.IssueRef) (*hierarchy.IssueRef, error) {
+		var q topParentQuery
+		if err := client.Query(ctx, &q, hierarchy.IssueVariables(ref)); err != nil {
 			return nil, fmt.Errorf("query error: %w", err)
 		}
+		issues[ref] = q.Repository.Issue.IssueFragment
+		return q.Repository.Issue.Parent.IssueRef(), nil
+	})
+	if err != nil {
+		return nil, err
+	}
 
-		current := int(q.Repository.Issue.Number)
-		if visitedIssues[current] {
-			fmt.Printf("Loop detected at issue %d\n", current)
-			return convertToIssue(&q.Repository.Issue), nil
-		}
-		visitedIssues[current] = true
-
-		if q.Repository.Issue.Parent.Number == 0 {
-			fmt.Printf("No parent found for issue %d\n", current)
-			return convertToIssue(&q.Repository.Issue), nil
-		}
-
-		parentNumber := int(q.Repository.Issue.Parent.Number)
-		fmt.Printf("Issue: %d -> Parent: %d\n", current, parentNumber)
-
-		if parentNumber <= 0 {
-			fmt.Printf("Invalid parent number %d for issue %d\n", parentNumber, current)
-			return convertToIssue(&q.Repository.Issue), nil
-		}
-
-		variables["issueNumber"] = githubv4.Int(parentNumber)
+	for i := 1; i < len(chain); i++ {
+		fmt.Printf("Issue: %s -> Parent: %s\n", chain[i-1], chain[i])
 	}
+	if loop {
+		fmt.Printf("Issue: %s -> Parent: %s\n", chain[len(chain)-1], top)
+		fmt.Printf("Loop detected at issue %s\n", top)
+	} else {
+		fmt.Printf("No parent found for issue %s\n", top)
+	}
+
+	topIssue := issues[top]
+	return convertToIssue(&topIssue, chain), nil
 }
 
-func convertToIssue(gqlIssue *IssueFragment) *Issue {
+func convertToIssue(gqlIssue *IssueFragment, chain []hierarchy.IssueRef) *Issue {
 	labels := make([]Label, len(gqlIssue.Labels.Nodes))
 	for i, label := range gqlIssue.Labels.Nodes {
 		labels[i] = Label{
@@ -129,10 +126,18 @@ func convertToIssue(gqlIssue *IssueFragment) *Issue {
 			URL:    string(gqlIssue.Url),
 			State:  string(gqlIssue.State),
 		}},
+		ParentChain: chain,
 	}
 }
 
 func main() {
+	showVersion := flag.Bool("version", false, "バージョン情報を表示して終了")
+	flag.Parse()
+	if *showVersion || os.Getenv("VERSION") == "true" {
+		fmt.Println(version.Info())
+		return
+	}
+
 	godotenv.Load()
 	org := os.Getenv("ORG")
 	repo := os.Getenv("REPO")
@@ -146,7 +151,17 @@ func main() {
 		&oauth2.Token{AccessToken: os.Getenv("GITHUB_TOKEN")},
 	)
 	httpClient := oauth2.NewClient(context.Background(), src)
-	client := githubv4.NewClient(httpClient)
+	httpClient.Transport = useragent.Wrap(httpClient.Transport, useragent.FromEnv())
+
+	// AUDIT_LOG が指定されていれば API 呼び出しを JSON Lines で記録
+	transport, closeAudit, err := audit.FromEnv(httpClient.Transport)
+	if err != nil {
+		panic(err)
+	}
+	defer closeAudit()
+	httpClient.Transport = transport
+	// GITHUB_BASE_URL が指定されていれば GitHub Enterprise Server の API に接続
+	client := githubv4.NewEnterpriseClient(githost.GraphQLURL(), httpClient)
 
 	issue, err := getTopParentIssue(context.Background(), client, org, repo, no)
 	if err != nil {
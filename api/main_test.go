@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"api/hierarchy"
+	"api/hierarchy/hierarchytest"
+)
+
+func TestGetTopParentIssueCrossRepository(t *testing.T) {
+	child := hierarchy.IssueRef{Org: "org", Repo: "app", Number: 3}
+	parent := hierarchy.IssueRef{Org: "org", Repo: "roadmap", Number: 2}
+	root := hierarchy.IssueRef{Org: "other", Repo: "epics", Number: 1}
+	client := &hierarchytest.FakeClient{Parents: map[hierarchy.IssueRef]*hierarchy.IssueRef{
+		child:  &parent,
+		parent: &root,
+		root:   nil,
+	}}
+
+	issue, err := getTopParentIssue(context.Background(), client, child.Org, child.Repo, child.Number)
+	if err != nil {
+		t.Fatalf("getTopParentIssue() error = %v", err)
+	}
+
+	if client.Queries != 3 {
+		t.Errorf("queries = %d, want 3", client.Queries)
+	}
+
+	want := []hierarchy.IssueRef{child, parent, root}
+	if !reflect.DeepEqual(issue.ParentChain, want) {
+		t.Errorf("ParentChain = %v, want %v", issue.ParentChain, want)
+	}
+	if got := issue.LinkedItems[0]; got.Number != 1 || got.URL != "https://github.com/other/epics/issues/1" {
+		t.Errorf("root = #%d %s, want other/epics#1", got.Number, got.URL)
+	}
+}
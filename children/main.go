@@ -2,10 +2,16 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
 	"os"
 
+	"common/audit"
+	"common/githost"
+	"common/useragent"
+	"common/version"
+
 	"github.com/joho/godotenv"
 	"github.com/shurcooL/githubv4"
 	"golang.org/x/oauth2"
@@ -29,6 +35,13 @@ var query struct {
 }
 
 func main() {
+	showVersion := flag.Bool("version", false, "バージョン情報を表示して終了")
+	flag.Parse()
+	if *showVersion || os.Getenv("VERSION") == "true" {
+		fmt.Println(version.Info())
+		return
+	}
+
 	godotenv.Load()
 	org := os.Getenv("ORG")
 	repo := os.Getenv("REPO")
@@ -52,7 +65,17 @@ func main() {
 		&oauth2.Token{AccessToken: githubToken},
 	)
 	httpClient := oauth2.NewClient(context.Background(), src)
-	client := githubv4.NewClient(httpClient)
+	httpClient.Transport = useragent.Wrap(httpClient.Transport, useragent.FromEnv())
+
+	// AUDIT_LOG が指定されていれば API 呼び出しを JSON Lines で記録
+	transport, closeAudit, err := audit.FromEnv(httpClient.Transport)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer closeAudit()
+	httpClient.Transport = transport
+	// GITHUB_BASE_URL が指定されていれば GitHub Enterprise Server の API に接続
+	client := githubv4.NewEnterpriseClient(githost.GraphQLURL(), httpClient)
 
 	// GraphQLクエリの変数を設定
 	variables := map[string]interface{}{
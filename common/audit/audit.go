@@ -0,0 +1,156 @@
+// Package audit は AUDIT_LOG で指定されたファイルに API 呼び出しを JSON Lines で記録する
+package audit
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// 1リクエストごとに1行書き出す監査ログのエントリ
+// クエリ本文とヘッダーは記録せず、GraphQL の query のハッシュのみ残す
+type auditEntry struct {
+	Timestamp      time.Time `json:"timestamp"`
+	Method         string    `json:"method"`
+	URL            string    `json:"url"`
+	QueryHash      string    `json:"query_hash"`
+	ResponseStatus int       `json:"response_status"`
+	DurationMs     int64     `json:"duration_ms"`
+}
+
+// FromEnv は AUDIT_LOG が指定されていれば rt を監査ログ付きでラップして返す
+// 返される close は実行の最後に呼び、サマリーを出力してファイルを閉じる
+// AUDIT_LOG が未設定なら rt をそのまま返す
+func FromEnv(rt http.RoundTripper) (http.RoundTripper, func(), error) {
+	auditPath := os.Getenv("AUDIT_LOG")
+	if auditPath == "" {
+		return rt, func() {}, nil
+	}
+
+	auditFile, err := os.OpenFile(auditPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, nil, fmt.Errorf("監査ログを開けません: %w", err)
+	}
+
+	a := newAuditRoundTripper(rt, auditFile)
+	return a, func() {
+		a.logSummary()
+		auditFile.Close()
+	}, nil
+}
+
+// RoundTripper をラップして API 呼び出しを監査ログに記録
+type auditRoundTripper struct {
+	rt  http.RoundTripper
+	enc *json.Encoder
+
+	mu       sync.Mutex
+	calls    int
+	duration time.Duration
+	bytes    int64
+}
+
+func newAuditRoundTripper(rt http.RoundTripper, w io.Writer) *auditRoundTripper {
+	return &auditRoundTripper{
+		rt:  rt,
+		enc: json.NewEncoder(w),
+	}
+}
+
+func (a *auditRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req = req.Clone(req.Context())
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	hash := sha256.Sum256(queryOf(body))
+
+	start := time.Now()
+	resp, err := a.rt.RoundTrip(req)
+	elapsed := time.Since(start)
+
+	entry := auditEntry{
+		Timestamp:  start.UTC(),
+		Method:     req.Method,
+		URL:        req.URL.Scheme + "://" + req.URL.Host + req.URL.Path,
+		QueryHash:  hex.EncodeToString(hash[:]),
+		DurationMs: elapsed.Milliseconds(),
+	}
+	if resp != nil {
+		entry.ResponseStatus = resp.StatusCode
+		resp.Body = &countingReadCloser{rc: resp.Body, audit: a}
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.calls++
+	a.duration += elapsed
+	a.bytes += int64(len(body))
+	if encErr := a.enc.Encode(entry); encErr != nil {
+		log.Printf("Error writing audit log: %v", encErr)
+	}
+
+	return resp, err
+}
+
+// 同じクエリを変数に関係なく集計できるよう、GraphQL リクエストは query フィールドだけをハッシュする
+// REST 呼び出しなど query を持たない本文はそのままハッシュする
+func queryOf(body []byte) []byte {
+	var req struct {
+		Query string `json:"query"`
+	}
+	if err := json.Unmarshal(body, &req); err == nil && req.Query != "" {
+		return []byte(req.Query)
+	}
+	return body
+}
+
+func (a *auditRoundTripper) logSummary() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	log.Printf("Total API calls: %d, Total duration: %.1fs, Total data transferred: %s",
+		a.calls, a.duration.Seconds(), formatBytes(a.bytes))
+}
+
+// レスポンス本文の読み込み量を監査ログの転送量に加算
+type countingReadCloser struct {
+	rc    io.ReadCloser
+	audit *auditRoundTripper
+}
+
+func (c *countingReadCloser) Read(p []byte) (int, error) {
+	n, err := c.rc.Read(p)
+	c.audit.mu.Lock()
+	c.audit.bytes += int64(n)
+	c.audit.mu.Unlock()
+	return n, err
+}
+
+func (c *countingReadCloser) Close() error {
+	return c.rc.Close()
+}
+
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1fMB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1fKB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%dB", n)
+	}
+}
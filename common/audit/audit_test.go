@@ -0,0 +1,171 @@
+package audit
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+)
+
+const testQuery = "query($n:Int!){repository(owner:\"o\",name:\"r\"){issue(number:$n){title}}}"
+
+func TestAuditRoundTripper(t *testing.T) {
+	const response = `{"data":{"repository":{"issue":{"title":"t"}}}}`
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = append(received, string(body))
+		io.WriteString(w, response)
+	}))
+	defer server.Close()
+
+	var auditLog bytes.Buffer
+	rt := newAuditRoundTripper(http.DefaultTransport, &auditLog)
+	client := &http.Client{Transport: rt}
+
+	bodies := []string{
+		fmt.Sprintf(`{"query":%q,"variables":{"n":1}}`, testQuery),
+		fmt.Sprintf(`{"query":%q,"variables":{"n":2}}`, testQuery),
+	}
+	var sent int
+	for _, body := range bodies {
+		resp, err := client.Post(server.URL+"/graphql?access_token=secret", "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		io.ReadAll(resp.Body)
+		resp.Body.Close()
+		sent += len(body)
+	}
+
+	if !reflect.DeepEqual(received, bodies) {
+		t.Errorf("server received %q, want %q", received, bodies)
+	}
+
+	lines := strings.Split(strings.TrimSpace(auditLog.String()), "\n")
+	if len(lines) != len(bodies) {
+		t.Fatalf("got %d audit lines, want %d:\n%s", len(lines), len(bodies), auditLog.String())
+	}
+	queryHash := sha256.Sum256([]byte(testQuery))
+	wantKeys := []string{"duration_ms", "method", "query_hash", "response_status", "timestamp", "url"}
+	for i, line := range lines {
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("line %d: %v", i, err)
+		}
+		var keys []string
+		for k := range entry {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		if !reflect.DeepEqual(keys, wantKeys) {
+			t.Errorf("line %d keys = %v, want %v", i, keys, wantKeys)
+		}
+		if entry["method"] != http.MethodPost || entry["response_status"] != float64(http.StatusOK) {
+			t.Errorf("line %d method/status = %v %v, want POST 200", i, entry["method"], entry["response_status"])
+		}
+		if entry["url"] != server.URL+"/graphql" {
+			t.Errorf("line %d url = %v, want %s", i, entry["url"], server.URL+"/graphql")
+		}
+		if entry["query_hash"] != hex.EncodeToString(queryHash[:]) {
+			t.Errorf("line %d query_hash = %v, want hash of the query field only", i, entry["query_hash"])
+		}
+	}
+
+	var summary bytes.Buffer
+	log.SetOutput(&summary)
+	defer log.SetOutput(os.Stderr)
+	rt.logSummary()
+
+	want := fmt.Sprintf(`Total API calls: 2, Total duration: \d+\.\ds, Total data transferred: %dB$`, sent+2*len(response))
+	if !regexp.MustCompile(want).MatchString(strings.TrimSpace(summary.String())) {
+		t.Errorf("summary = %q, want match %q", summary.String(), want)
+	}
+}
+
+func TestQueryOf(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{name: "GraphQL request", body: `{"query":"{viewer{login}}","variables":{"n":1}}`, want: "{viewer{login}}"},
+		{name: "REST body", body: `{"title":"x"}`, want: `{"title":"x"}`},
+		{name: "not JSON", body: "a=b", want: "a=b"},
+		{name: "empty", body: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(queryOf([]byte(tt.body))); got != tt.want {
+				t.Errorf("queryOf(%q) = %q, want %q", tt.body, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{0, "0B"},
+		{1023, "1023B"},
+		{1536, "1.5KB"},
+		{1258291, "1.2MB"},
+	}
+
+	for _, tt := range tests {
+		if got := formatBytes(tt.n); got != tt.want {
+			t.Errorf("formatBytes(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("AUDIT_LOG", "")
+	rt, closeAudit, err := FromEnv(http.DefaultTransport)
+	if err != nil || rt != http.DefaultTransport {
+		t.Fatalf("FromEnv() without AUDIT_LOG = %v, %v, want the transport unchanged", rt, err)
+	}
+	closeAudit()
+
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	t.Setenv("AUDIT_LOG", path)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	rt, closeAudit, err = FromEnv(http.DefaultTransport)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := (&http.Client{Transport: rt}).Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+	closeAudit()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(data), "\n"); n != 1 {
+		t.Errorf("audit log has %d lines, want 1:\n%s", n, data)
+	}
+}
@@ -0,0 +1,33 @@
+// Package githost は GITHUB_BASE_URL から接続先の GitHub を決める
+package githost
+
+import (
+	"os"
+	"strings"
+)
+
+// DefaultBaseURL は GITHUB_BASE_URL が未設定のときに使う github.com の URL
+const DefaultBaseURL = "https://github.com"
+
+// BaseURL は GITHUB_BASE_URL を末尾の / を除いて返す。未設定なら DefaultBaseURL を返す
+// GitHub Enterprise Server では https://github.example.com のように指定する
+func BaseURL() string {
+	if baseURL := os.Getenv("GITHUB_BASE_URL"); baseURL != "" {
+		return strings.TrimSuffix(baseURL, "/")
+	}
+	return DefaultBaseURL
+}
+
+// IsEnterprise は GITHUB_BASE_URL が github.com 以外を指しているかを返す
+func IsEnterprise() bool {
+	return BaseURL() != DefaultBaseURL
+}
+
+// GraphQLURL は GraphQL API のエンドポイントを返す
+// github.com では https://api.github.com/graphql、GitHub Enterprise Server では <BaseURL>/api/graphql
+func GraphQLURL() string {
+	if !IsEnterprise() {
+		return "https://api.github.com/graphql"
+	}
+	return BaseURL() + "/api/graphql"
+}
@@ -0,0 +1,54 @@
+package githost
+
+import "testing"
+
+func TestGraphQLURL(t *testing.T) {
+	tests := []struct {
+		name           string
+		baseURL        string
+		wantBaseURL    string
+		wantEnterprise bool
+		want           string
+	}{
+		{
+			name:        "unset",
+			wantBaseURL: "https://github.com",
+			want:        "https://api.github.com/graphql",
+		},
+		{
+			name:        "github.com with trailing slash",
+			baseURL:     "https://github.com/",
+			wantBaseURL: "https://github.com",
+			want:        "https://api.github.com/graphql",
+		},
+		{
+			name:           "GHE host",
+			baseURL:        "https://github.example.com",
+			wantBaseURL:    "https://github.example.com",
+			wantEnterprise: true,
+			want:           "https://github.example.com/api/graphql",
+		},
+		{
+			name:           "GHE with path prefix",
+			baseURL:        "https://example.com/github/",
+			wantBaseURL:    "https://example.com/github",
+			wantEnterprise: true,
+			want:           "https://example.com/github/api/graphql",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("GITHUB_BASE_URL", tt.baseURL)
+			if got := BaseURL(); got != tt.wantBaseURL {
+				t.Errorf("BaseURL() = %q, want %q", got, tt.wantBaseURL)
+			}
+			if got := IsEnterprise(); got != tt.wantEnterprise {
+				t.Errorf("IsEnterprise() = %v, want %v", got, tt.wantEnterprise)
+			}
+			if got := GraphQLURL(); got != tt.want {
+				t.Errorf("GraphQLURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+// Package useragent はすべてのツールの HTTP クライアントに User-Agent を付与する
+package useragent
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+
+	"common/version"
+)
+
+// Default は "github-issue-analyzer/<バージョン> (go)" 形式の User-Agent を返す
+func Default() string {
+	v := version.Version()
+	if v == "" {
+		v = "1.0"
+	}
+	return fmt.Sprintf("%s/%s (go)", version.Name, v)
+}
+
+// FromEnv は HTTP_USER_AGENT が設定されていればそれを、なければ Default を返す
+// 企業内プロキシ等で User-Agent が制限される場合に上書きできるようにするため
+func FromEnv() string {
+	if userAgent := os.Getenv("HTTP_USER_AGENT"); userAgent != "" {
+		return userAgent
+	}
+	return Default()
+}
+
+// RoundTripper をラップして User-Agent ヘッダーを上書き
+type roundTripper struct {
+	rt        http.RoundTripper
+	userAgent string
+}
+
+// Wrap は rt を経由するすべてのリクエストの User-Agent を userAgent に置き換える
+func Wrap(rt http.RoundTripper, userAgent string) http.RoundTripper {
+	return roundTripper{rt: rt, userAgent: userAgent}
+}
+
+func (u roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	// RoundTripper はリクエストを変更してはいけないため複製してから設定
+	req = req.Clone(req.Context())
+	req.Header.Set("User-Agent", u.userAgent)
+	return u.rt.RoundTrip(req)
+}
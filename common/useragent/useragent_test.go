@@ -0,0 +1,74 @@
+package useragent
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// recordingRoundTripper captures the User-Agent of every request it receives.
+type recordingRoundTripper struct {
+	userAgents []string
+}
+
+func (r *recordingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	r.userAgents = append(r.userAgents, req.Header.Get("User-Agent"))
+	return httptest.NewRecorder().Result(), nil
+}
+
+func TestWrapSetsHeaderOnEachRequest(t *testing.T) {
+	t.Setenv("HTTP_USER_AGENT", "")
+	rec := &recordingRoundTripper{}
+	client := &http.Client{Transport: Wrap(rec, FromEnv())}
+
+	for i := 0; i < 3; i++ {
+		resp, err := client.Get("https://api.github.com/graphql")
+		if err != nil {
+			t.Fatalf("request %d: %v", i, err)
+		}
+		resp.Body.Close()
+	}
+
+	want := "github-issue-analyzer/1.0 (go)"
+	if len(rec.userAgents) != 3 {
+		t.Fatalf("got %d requests, want 3", len(rec.userAgents))
+	}
+	for i, got := range rec.userAgents {
+		if got != want {
+			t.Errorf("request %d User-Agent = %q, want %q", i, got, want)
+		}
+	}
+}
+
+func TestFromEnvOverridesDefault(t *testing.T) {
+	t.Setenv("HTTP_USER_AGENT", "corp-approved-client/2.3")
+	rec := &recordingRoundTripper{}
+	rt := Wrap(rec, FromEnv())
+
+	req := httptest.NewRequest(http.MethodPost, "https://api.github.com/graphql", nil)
+	if _, err := rt.RoundTrip(req); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := rec.userAgents[0]; got != "corp-approved-client/2.3" {
+		t.Errorf("User-Agent = %q, want corp-approved-client/2.3", got)
+	}
+}
+
+func TestWrapLeavesCallerRequestUnchanged(t *testing.T) {
+	rec := &recordingRoundTripper{}
+	rt := Wrap(rec, "github-issue-analyzer/test (go)")
+
+	req := httptest.NewRequest(http.MethodPost, "https://api.github.com/graphql", nil)
+	req.Header.Set("User-Agent", "caller/1.0")
+	if _, err := rt.RoundTrip(req); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := req.Header.Get("User-Agent"); got != "caller/1.0" {
+		t.Errorf("caller User-Agent = %q, want caller/1.0", got)
+	}
+	if got := rec.userAgents[0]; got != "github-issue-analyzer/test (go)" {
+		t.Errorf("sent User-Agent = %q, want github-issue-analyzer/test (go)", got)
+	}
+}
@@ -0,0 +1,59 @@
+// Package version はすべてのツールで共有するバージョン情報を提供する
+package version
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+// Name はバージョン表示と User-Agent に使うツール名
+const Name = "github-issue-analyzer"
+
+// go build -ldflags "-X common/version.version=1.0.0 -X common/version.buildTime=2024-01-15T09:00:00Z" で埋め込む
+var (
+	version   = ""
+	buildTime = ""
+)
+
+// Version は "v" を除いたバージョンを返す
+// ldflags の指定がなければメインモジュールのバージョンを使い、どちらもなければ空文字を返す
+func Version() string {
+	if version != "" {
+		return strings.TrimPrefix(version, "v")
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return strings.TrimPrefix(info.Main.Version, "v")
+	}
+	return ""
+}
+
+// Info は --version で表示する1行のバージョン情報を返す
+func Info() string {
+	v := "dev"
+	if av := Version(); av != "" {
+		v = "v" + av
+	}
+
+	revision := "unknown"
+	built := buildTime
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				revision = setting.Value[:min(7, len(setting.Value))]
+			case "vcs.time":
+				if built == "" {
+					built = setting.Value
+				}
+			}
+		}
+	}
+	if built == "" {
+		built = "unknown"
+	}
+
+	return fmt.Sprintf("%s %s (git: %s, built: %s, go: %s)",
+		Name, v, revision, built, strings.TrimPrefix(runtime.Version(), "go"))
+}
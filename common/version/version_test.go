@@ -0,0 +1,52 @@
+package version
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestInfo(t *testing.T) {
+	goVersion := strings.TrimPrefix(runtime.Version(), "go")
+
+	tests := []struct {
+		name        string
+		version     string
+		buildTime   string
+		wantVersion string
+		want        string
+	}{
+		{
+			name:        "no ldflags",
+			wantVersion: "",
+			want:        "github-issue-analyzer dev (git: unknown, built: unknown, go: " + goVersion + ")",
+		},
+		{
+			name:        "ldflags with v prefix",
+			version:     "v1.2.0",
+			buildTime:   "2024-01-15T09:00:00Z",
+			wantVersion: "1.2.0",
+			want:        "github-issue-analyzer v1.2.0 (git: unknown, built: 2024-01-15T09:00:00Z, go: " + goVersion + ")",
+		},
+		{
+			name:        "ldflags without v prefix",
+			version:     "1.2.0",
+			wantVersion: "1.2.0",
+			want:        "github-issue-analyzer v1.2.0 (git: unknown, built: unknown, go: " + goVersion + ")",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			version, buildTime = tt.version, tt.buildTime
+			t.Cleanup(func() { version, buildTime = "", "" })
+
+			if got := Version(); got != tt.wantVersion {
+				t.Errorf("Version() = %q, want %q", got, tt.wantVersion)
+			}
+			if got := Info(); got != tt.want {
+				t.Errorf("Info() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
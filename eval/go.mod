@@ -13,3 +13,7 @@ require (
 	github.com/google/go-querystring v1.1.0 // indirect
 	github.com/shurcooL/graphql v0.0.0-20230722043721-ed46e5a46466 // indirect
 )
+
+require common v0.0.0
+
+replace common => ../common
@@ -3,11 +3,19 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"flag"
 	"fmt"
 	"log"
 	"os"
+	"regexp"
+	"strings"
 	"time"
 
+	"common/audit"
+	"common/githost"
+	"common/useragent"
+	"common/version"
+
 	"github.com/google/go-github/v69/github"
 	"github.com/joho/godotenv"
 	"github.com/shurcooL/githubv4"
@@ -240,6 +248,113 @@ func findLinkedPRs(ctx context.Context, client *github.Client, rateLimit *RateLi
 	return linkedPRs, nil
 }
 
+type Discussion struct {
+	Number         int        `json:"number"`
+	Title          string     `json:"title"`
+	URL            string     `json:"url"`
+	State          string     `json:"state"`
+	AnswerChosenAt *time.Time `json:"answer_chosen_at,omitempty"`
+	CommentCount   int        `json:"comment_count"`
+}
+
+type discussionSearchQuery struct {
+	Search struct {
+		Nodes []struct {
+			Discussion struct {
+				Number         githubv4.Int
+				Title          githubv4.String
+				Url            githubv4.String
+				Body           githubv4.String
+				Closed         githubv4.Boolean
+				AnswerChosenAt *githubv4.DateTime
+				Comments       struct {
+					TotalCount githubv4.Int
+				}
+			} `graphql:"... on Discussion"`
+		}
+		PageInfo struct {
+			EndCursor   githubv4.String
+			HasNextPage bool
+		}
+	} `graphql:"search(query: $searchQuery, type: DISCUSSION, first: 100, after: $cursor)"`
+}
+
+// maxDiscussionSearchPages caps how many pages of search hits are scanned per issue
+const maxDiscussionSearchPages = 5
+
+// issueReferencePattern matches "#N", "org/repo#N" or the issue URL under baseURL.
+// A short reference must start the text or follow whitespace or an opening bracket,
+// so references such as other-org/repo#N or a.org/repo#N do not count.
+func issueReferencePattern(baseURL, org, repo string, issueNumber int) *regexp.Regexp {
+	repoPath := regexp.QuoteMeta(org) + "/" + regexp.QuoteMeta(repo)
+	return regexp.MustCompile(fmt.Sprintf(`(?i)(?:^|[\s(\[])(?:%s)?#%d\b|%s/%s/issues/%d\b`,
+		repoPath, issueNumber, regexp.QuoteMeta(strings.TrimSuffix(baseURL, "/")), repoPath, issueNumber))
+}
+
+// Discussions are not exposed by the REST search API, so they are looked up via GraphQL search.
+// Search hits only contain the number somewhere, so a discussion is kept only when its body
+// actually references the issue.
+func fetchLinkedDiscussions(ctx context.Context, client *githubv4.Client, rateLimit *RateLimitHandler, org, repo string, issueNumber int) ([]Discussion, error) {
+	var linkedDiscussions []Discussion
+	processedDiscussions := make(map[int]bool)
+	reference := issueReferencePattern(githost.BaseURL(), org, repo, issueNumber)
+
+	variables := map[string]interface{}{
+		"searchQuery": githubv4.String(fmt.Sprintf("repo:%s/%s %d in:body", org, repo, issueNumber)),
+		"cursor":      (*githubv4.String)(nil),
+	}
+
+	for page := 1; ; page++ {
+		if err := rateLimit.WaitForGraphQLRateLimit(ctx); err != nil {
+			return nil, fmt.Errorf("rate limit error: %v", err)
+		}
+
+		var q discussionSearchQuery
+		if err := client.Query(ctx, &q, variables); err != nil {
+			return nil, fmt.Errorf("error searching discussions: %v", err)
+		}
+
+		for _, node := range q.Search.Nodes {
+			d := node.Discussion
+			if d.Number == 0 || processedDiscussions[int(d.Number)] {
+				continue
+			}
+			processedDiscussions[int(d.Number)] = true
+			if !reference.MatchString(string(d.Body)) {
+				continue
+			}
+
+			discussion := Discussion{
+				Number:       int(d.Number),
+				Title:        string(d.Title),
+				URL:          string(d.Url),
+				State:        "OPEN",
+				CommentCount: int(d.Comments.TotalCount),
+			}
+			if d.Closed {
+				discussion.State = "CLOSED"
+			}
+			if d.AnswerChosenAt != nil {
+				answerChosenAt := d.AnswerChosenAt.Time
+				discussion.AnswerChosenAt = &answerChosenAt
+			}
+
+			linkedDiscussions = append(linkedDiscussions, discussion)
+		}
+
+		if !q.Search.PageInfo.HasNextPage {
+			break
+		}
+		if page >= maxDiscussionSearchPages {
+			log.Printf("Discussion search for issue #%d stopped after %d pages", issueNumber, maxDiscussionSearchPages)
+			break
+		}
+		variables["cursor"] = githubv4.String(q.Search.PageInfo.EndCursor)
+	}
+
+	return linkedDiscussions, nil
+}
+
 type IssueOutput struct {
 	Number    int        `json:"number"`
 	Title     string     `json:"title"`
@@ -256,9 +371,17 @@ type IssueOutput struct {
 		Labels []Label `json:"labels"`
 	} `json:"sub_issues"`
 	LinkedPullRequests []PullRequest `json:"linked_pull_requests"`
+	LinkedDiscussions  []Discussion  `json:"linked_discussions"`
 }
 
 func main() {
+	showVersion := flag.Bool("version", false, "Print version information and exit")
+	flag.Parse()
+	if *showVersion || os.Getenv("VERSION") == "true" {
+		fmt.Println(version.Info())
+		return
+	}
+
 	godotenv.Load()
 	token := os.Getenv("GITHUB_TOKEN")
 	if token == "" {
@@ -272,9 +395,25 @@ func main() {
 		&oauth2.Token{AccessToken: token},
 	)
 	tc := oauth2.NewClient(ctx, ts)
+	tc.Transport = useragent.Wrap(tc.Transport, useragent.FromEnv())
+
+	// Record every API call as JSON Lines when AUDIT_LOG is set
+	transport, closeAudit, err := audit.FromEnv(tc.Transport)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer closeAudit()
+	tc.Transport = transport
 
+	// Talk to the GitHub Enterprise Server API when GITHUB_BASE_URL is set
 	restClient := github.NewClient(tc)
-	graphqlClient := githubv4.NewClient(tc)
+	if githost.IsEnterprise() {
+		restClient, err = restClient.WithEnterpriseURLs(githost.BaseURL(), githost.BaseURL())
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	graphqlClient := githubv4.NewEnterpriseClient(githost.GraphQLURL(), tc)
 	rateLimitHandler := NewRateLimitHandler(restClient, graphqlClient)
 
 	variables := map[string]interface{}{
@@ -351,6 +490,13 @@ func main() {
 				issueData.LinkedPullRequests = linkedPRs
 			}
 
+			linkedDiscussions, err := fetchLinkedDiscussions(ctx, graphqlClient, rateLimitHandler, org, repo, int(issue.Number))
+			if err != nil {
+				log.Printf("Error fetching linked discussions for issue #%d: %v", issue.Number, err)
+			} else {
+				issueData.LinkedDiscussions = linkedDiscussions
+			}
+
 			output, err := json.MarshalIndent(issueData, "", "  ")
 			if err != nil {
 				log.Fatal(err)
@@ -0,0 +1,47 @@
+package main
+
+import "testing"
+
+func TestIssueReferencePattern(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want bool
+	}{
+		{name: "short reference", body: "Follow-up of #12", want: true},
+		{name: "short reference at start", body: "#12 needs a decision", want: true},
+		{name: "short reference in brackets", body: "Discussed earlier (#12) and [#12]", want: true},
+		{name: "repo reference", body: "See org/repo#12", want: true},
+		{name: "repo reference with different case", body: "See Org/Repo#12", want: true},
+		{name: "issue URL", body: "https://github.com/org/repo/issues/12", want: true},
+		{name: "issue URL with comment anchor", body: "https://github.com/org/repo/issues/12#issuecomment-1", want: true},
+		{name: "longer number", body: "See #123", want: false},
+		{name: "bare number", body: "We expect 12 people", want: false},
+		{name: "other repository", body: "See org/other#12", want: false},
+		{name: "org with hyphen prefix", body: "See other-org/repo#12", want: false},
+		{name: "org with dot prefix", body: "See a.org/repo#12", want: false},
+		{name: "word before hash", body: "abc#12", want: false},
+		{name: "issue URL on another host", body: "https://gitlab.com/org/repo/issues/12", want: false},
+		{name: "issue URL in other org", body: "https://github.com/other-org/repo/issues/12", want: false},
+		{name: "pull request URL", body: "https://github.com/org/repo/pull/12", want: false},
+	}
+
+	reference := issueReferencePattern("https://github.com", "org", "repo", 12)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := reference.MatchString(tt.body); got != tt.want {
+				t.Errorf("MatchString(%q) = %v, want %v", tt.body, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIssueReferencePatternEnterprise(t *testing.T) {
+	reference := issueReferencePattern("https://example.com/github/", "org", "repo", 12)
+	if !reference.MatchString("https://example.com/github/org/repo/issues/12") {
+		t.Error("GHE issue URL did not match")
+	}
+	if reference.MatchString("https://github.com/org/repo/issues/12") {
+		t.Error("github.com issue URL matched with a GHE base URL")
+	}
+}
@@ -9,3 +9,7 @@ require (
 )
 
 require github.com/google/go-querystring v1.1.0 // indirect
+
+require common v0.0.0
+
+replace common => ../common
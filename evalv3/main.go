@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
 	"os"
@@ -9,6 +10,11 @@ import (
 	"strings"
 	"time"
 
+	"common/audit"
+	"common/githost"
+	"common/useragent"
+	"common/version"
+
 	"github.com/google/go-github/v69/github"
 	"github.com/joho/godotenv"
 	"golang.org/x/oauth2"
@@ -37,6 +43,13 @@ type PullRequestInfo struct {
 }
 
 func main() {
+	showVersion := flag.Bool("version", false, "バージョン情報を表示して終了")
+	flag.Parse()
+	if *showVersion || os.Getenv("VERSION") == "true" {
+		fmt.Println(version.Info())
+		return
+	}
+
 	err := godotenv.Load()
 	if err != nil {
 		log.Fatal("Error loading .env file")
@@ -55,7 +68,24 @@ func main() {
 		&oauth2.Token{AccessToken: token},
 	)
 	tc := oauth2.NewClient(ctx, ts)
+	tc.Transport = useragent.Wrap(tc.Transport, useragent.FromEnv())
+
+	// AUDIT_LOG が指定されていれば API 呼び出しを JSON Lines で記録
+	transport, closeAudit, err := audit.FromEnv(tc.Transport)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer closeAudit()
+	tc.Transport = transport
+
+	// GITHUB_BASE_URL が指定されていれば GitHub Enterprise Server の API に接続
 	client := github.NewClient(tc)
+	if githost.IsEnterprise() {
+		client, err = client.WithEnterpriseURLs(githost.BaseURL(), githost.BaseURL())
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
 
 	// レート制限ハンドラーの初期化
 	rateLimitHandler := NewRateLimitHandler(client)
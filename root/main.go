@@ -2,10 +2,16 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"net/http"
 	"os"
 
+	"common/audit"
+	"common/githost"
+	"common/useragent"
+	"common/version"
+
 	"github.com/shurcooL/githubv4"
 	"golang.org/x/oauth2"
 )
@@ -35,6 +41,13 @@ type rootCheckQuery struct {
 }
 
 func main() {
+	showVersion := flag.Bool("version", false, "バージョン情報を表示して終了")
+	flag.Parse()
+	if *showVersion || os.Getenv("VERSION") == "true" {
+		fmt.Println(version.Info())
+		return
+	}
+
 	ctx := context.Background()
 	token := os.Getenv("GITHUB_TOKEN")
 	if token == "" {
@@ -50,8 +63,19 @@ func main() {
 	src := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token})
 	httpClient := oauth2.NewClient(ctx, src)
 	httpClient.Transport = headerRoundTripper{rt: httpClient.Transport}
+	httpClient.Transport = useragent.Wrap(httpClient.Transport, useragent.FromEnv())
+
+	// AUDIT_LOG が指定されていれば API 呼び出しを JSON Lines で記録
+	transport, closeAudit, err := audit.FromEnv(httpClient.Transport)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer closeAudit()
+	httpClient.Transport = transport
 
-	client := githubv4.NewClient(httpClient)
+	// GITHUB_BASE_URL が指定されていれば GitHub Enterprise Server の API に接続
+	client := githubv4.NewEnterpriseClient(githost.GraphQLURL(), httpClient)
 
 	var q rootCheckQuery
 	variables := map[string]interface{}{